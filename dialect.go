@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dialect isolates the SQL surface differences between backends (DSN
+// construction, database/schema bootstrap, placeholder syntax, and which
+// errors are duplicate-key vs. worth retrying) so loadData and
+// ensureDatabaseAndSchema can stay backend-agnostic.
+type Dialect interface {
+	// Name identifies the dialect for logging, independent of the -driver
+	// flag value a user picked to select it (mysql and mariadb both resolve
+	// to the same Dialect).
+	Name() string
+	// DriverName is the database/sql driver name passed to sql.Open.
+	DriverName() string
+	// DSN builds a connection string for one shard's endpoint. dbname may be
+	// empty to connect without selecting a database, e.g. to run
+	// CreateDatabase. cfg carries loader-wide settings (e.g. whether the
+	// infile loader needs allowAllFiles) that apply to every shard alike.
+	DSN(cfg Config, shard ShardConfig, dbname string) string
+	// CreateDatabase creates cfg.DBName if it does not already exist.
+	CreateDatabase(ctx context.Context, adminDB *sql.DB, name string) error
+	// SchemaDDL returns the statements that create the accounts, products,
+	// payments, and buying_stats tables (and any indexes), in order.
+	SchemaDDL() []string
+	// Placeholder returns the driver's bind-parameter syntax for the i-th
+	// (1-based) parameter in a statement.
+	Placeholder(i int) string
+	// IsDupKey reports whether err is a unique/primary-key violation.
+	IsDupKey(err error) bool
+	// IsRetryable reports whether err is transient and worth retrying the
+	// surrounding transaction (deadlock, lock-wait timeout, serialization
+	// failure, ...).
+	IsRetryable(err error) bool
+}
+
+var dialects = map[string]func() Dialect{}
+
+// registerDialect makes a Dialect available under -driver=name. Backend
+// implementations call this from an init() in a build-tag-gated file, so a
+// binary built with e.g. -tags nopostgres drops both the registration and
+// its driver import.
+func registerDialect(name string, factory func() Dialect) {
+	dialects[name] = factory
+}
+
+func dialectFor(name string) (Dialect, error) {
+	factory, ok := dialects[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown -driver %q (available: %s)", name, strings.Join(availableDialects(), ", "))
+	}
+	return factory(), nil
+}
+
+func availableDialects() []string {
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// buildInsertSQL renders "INSERT INTO table (cols) VALUES (<placeholders>)"
+// using the dialect's bind-parameter syntax.
+func buildInsertSQL(dialect Dialect, table string, cols []string) string {
+	ps := make([]string, len(cols))
+	for i := range cols {
+		ps[i] = dialect.Placeholder(i + 1)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(cols, ","), strings.Join(ps, ","))
+}
+
+// buildMultiInsertSQL renders a multi-row INSERT for nRows rows of cols,
+// numbering placeholders from 1 across the whole statement (required for
+// Postgres-style $N parameters; MySQL's "?" ignores the numbering).
+func buildMultiInsertSQL(dialect Dialect, table string, cols []string, nRows int) string {
+	rows := make([]string, nRows)
+	idx := 1
+	for r := 0; r < nRows; r++ {
+		ps := make([]string, len(cols))
+		for c := range cols {
+			ps[c] = dialect.Placeholder(idx)
+			idx++
+		}
+		rows[r] = "(" + strings.Join(ps, ",") + ")"
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", table, strings.Join(cols, ","), strings.Join(rows, ","))
+}
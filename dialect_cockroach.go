@@ -0,0 +1,38 @@
+//go:build !nocockroach && !nopostgres
+
+// cockroachDialect reuses postgresDialect, so it's only built alongside it;
+// -tags nopostgres disables cockroach support too.
+
+package main
+
+import "strings"
+
+func init() {
+	registerDialect("cockroach", func() Dialect { return cockroachDialect{} })
+}
+
+// cockroachDialect speaks the Postgres wire protocol and reuses
+// postgresDialect for DSN/placeholders/dup-key/retry detection (CockroachDB
+// reports the same 40001 serialization_failure code on a transaction
+// restart), overriding only what genuinely differs.
+type cockroachDialect struct {
+	postgresDialect
+}
+
+func (cockroachDialect) Name() string { return "cockroach" }
+
+// SchemaDDL drops only the two FK-column indexes (idx_bs_account,
+// idx_bs_product) from postgresIndexDDL: CockroachDB automatically creates
+// an index for every foreign-key column, so explicit ones on those same
+// columns fail with "index already exists" (see the storj codebase for the
+// same quirk). The non-FK secondary indexes still apply.
+func (cockroachDialect) SchemaDDL() []string {
+	ddl := postgresTableDDL()
+	for _, idx := range postgresIndexDDL() {
+		if strings.Contains(idx, "idx_bs_account") || strings.Contains(idx, "idx_bs_product") {
+			continue
+		}
+		ddl = append(ddl, idx)
+	}
+	return ddl
+}
@@ -0,0 +1,107 @@
+//go:build !nomysql
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL error numbers this dialect cares about.
+const (
+	mysqlErrDupEntry        = 1062
+	mysqlErrLockDeadlock    = 1213
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrLockNowait      = 1614
+)
+
+func init() {
+	registerDialect("mysql", func() Dialect { return mysqlDialect{} })
+	registerDialect("mariadb", func() Dialect { return mysqlDialect{} })
+}
+
+// mysqlDialect covers both MySQL and MariaDB, which share the same wire
+// protocol, error numbers, and DDL dialect for the schema this tool creates.
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string       { return "mysql" }
+func (mysqlDialect) DriverName() string { return "mysql" }
+
+func (mysqlDialect) DSN(cfg Config, shard ShardConfig, dbname string) string {
+	d := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&charset=utf8mb4,utf8&collation=utf8mb4_unicode_ci",
+		shard.User, shard.Password, shard.Host, shard.Port, dbname)
+	if cfg.Loader == loaderInfile {
+		// LOAD DATA LOCAL INFILE 'Reader::...' requires the client to opt in.
+		d += "&allowAllFiles=true"
+	}
+	return d
+}
+
+func (mysqlDialect) CreateDatabase(ctx context.Context, adminDB *sql.DB, name string) error {
+	_, err := adminDB.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", name))
+	return err
+}
+
+func (mysqlDialect) SchemaDDL() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS accounts (
+			a_uuid CHAR(36) PRIMARY KEY,
+			a_username VARCHAR(64) NOT NULL,
+			a_email VARCHAR(255) NOT NULL,
+			a_password VARCHAR(128) NOT NULL,
+			a_created_epoch BIGINT NOT NULL,
+			a_last_login_epoch BIGINT NOT NULL,
+			INDEX idx_accounts_email (a_email),
+			INDEX idx_accounts_created (a_created_epoch)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+		`CREATE TABLE IF NOT EXISTS products (
+			pr_uuid CHAR(36) PRIMARY KEY,
+			pr_name VARCHAR(255) NOT NULL,
+			pr_authors VARCHAR(512) NOT NULL,
+			pr_price DECIMAL(10,2) NOT NULL,
+			INDEX idx_products_price (pr_price)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+		`CREATE TABLE IF NOT EXISTS payments (
+			p_md5 CHAR(32) PRIMARY KEY,
+			p_amount DECIMAL(10,2) NOT NULL,
+			p_epoch BIGINT NOT NULL,
+			INDEX idx_payments_epoch (p_epoch)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+		`CREATE TABLE IF NOT EXISTS buying_stats (
+			bs_account_uuid CHAR(36) NOT NULL,
+			bs_product_uuid CHAR(36) NOT NULL,
+			bs_quantity INT NOT NULL,
+			bs_total_amount DECIMAL(10,2) NOT NULL,
+			bs_epoch BIGINT NOT NULL,
+			INDEX idx_bs_epoch (bs_epoch),
+			INDEX idx_bs_account (bs_account_uuid),
+			INDEX idx_bs_product (bs_product_uuid),
+			CONSTRAINT fk_bs_account FOREIGN KEY (bs_account_uuid) REFERENCES accounts(a_uuid) ON DELETE CASCADE,
+			CONSTRAINT fk_bs_product FOREIGN KEY (bs_product_uuid) REFERENCES products(pr_uuid) ON DELETE CASCADE
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+	}
+}
+
+func (mysqlDialect) Placeholder(int) string { return "?" }
+
+func (mysqlDialect) IsDupKey(err error) bool {
+	var myErr *mysql.MySQLError
+	return errors.As(err, &myErr) && myErr.Number == mysqlErrDupEntry
+}
+
+func (mysqlDialect) IsRetryable(err error) bool {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return false
+	}
+	switch myErr.Number {
+	case mysqlErrLockDeadlock, mysqlErrLockWaitTimeout, mysqlErrLockNowait:
+		return true
+	default:
+		return false
+	}
+}
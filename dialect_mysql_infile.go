@@ -0,0 +1,152 @@
+//go:build !nomysql
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func init() {
+	infileLoader = loadDataInfileMySQL
+}
+
+// loadDataInfileMySQL accumulates batchSize generated buying_stats rows per
+// worker and loads them via LOAD DATA LOCAL INFILE, streaming the rows
+// through a csv.Writer registered as a named in-memory reader
+// (mysql.RegisterReaderHandler) rather than writing a temp file to disk.
+func loadDataInfileMySQL(ctx context.Context, cfg Config, shardDBs []*sql.DB, dialect Dialect, accounts []Account, products []Product) error {
+	nShards := len(shardDBs)
+	var inserted uint64
+	var flushSeq uint64
+	shardInserted := make([]uint64, nShards)
+	recordsPerLog := recordsPerLog(cfg.DBRecordsToLoad, cfg.PcentOutput)
+	start := time.Now()
+
+	jobs := make(chan int, cfg.NumWorkers*4)
+	var wg sync.WaitGroup
+	errCh := make(chan error, cfg.NumWorkers)
+
+	for w := 0; w < cfg.NumWorkers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(time.Now().UnixNano() + int64(workerID*1000)))
+			acctSampler := newSampler(r, cfg, len(accounts))
+			prodSampler := newSampler(r, cfg, len(products))
+			bufs := make([][]BuyingStat, nShards)
+			for s := range bufs {
+				bufs[s] = make([]BuyingStat, 0, cfg.BatchSize)
+			}
+
+			flush := func(s int) bool {
+				if len(bufs[s]) == 0 {
+					return true
+				}
+				seq := atomic.AddUint64(&flushSeq, 1)
+				if err := loadInfileBatch(ctx, shardDBs[s], s, seq, bufs[s]); err != nil {
+					errCh <- err
+					return false
+				}
+
+				n := atomic.AddUint64(&inserted, uint64(len(bufs[s])))
+				sn := atomic.AddUint64(&shardInserted[s], uint64(len(bufs[s])))
+				if recordsPerLog > 0 {
+					for logged := n - uint64(len(bufs[s])) + 1; logged <= n; logged++ {
+						if int(logged)%recordsPerLog == 0 {
+							logShardProgress(cfg.Loader, s, sn, logged, cfg.DBRecordsToLoad, start)
+						}
+					}
+				}
+				bufs[s] = bufs[s][:0]
+				return int(n) < cfg.DBRecordsToLoad
+			}
+
+			for idx := range jobs {
+				bs := generateBuyingStat(r, accounts, products, acctSampler, prodSampler)
+				s := shardIndex(cfg, shardKeyValue(cfg, bs), idx, cfg.DBRecordsToLoad, nShards)
+				bufs[s] = append(bufs[s], bs)
+				if len(bufs[s]) >= cfg.BatchSize {
+					if !flush(s) {
+						return
+					}
+				}
+			}
+			for s := range bufs {
+				if !flush(s) {
+					return
+				}
+			}
+		}(w)
+	}
+
+	if err := feedJobs(jobs, cfg.DBRecordsToLoad, errCh); err != nil {
+		return err
+	}
+
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return err
+	default:
+	}
+
+	final := atomic.LoadUint64(&inserted)
+	if int(final) < cfg.DBRecordsToLoad {
+		return fmt.Errorf("only inserted %d/%d records (duplicate keys likely); try increasing dbRecords2Process", final, cfg.DBRecordsToLoad)
+	}
+
+	logFinal(cfg.Loader, final, start)
+	logShardTotals(shardInserted)
+	return nil
+}
+
+// loadInfileBatch runs one LOAD DATA LOCAL INFILE against buying_stats on
+// shard for a flush.
+func loadInfileBatch(ctx context.Context, db *sql.DB, shard int, seq uint64, records []BuyingStat) error {
+	return loadInfileTable(ctx, db, "buying_stats", buyingCols, shard, seq, len(records), func(i int, w *csv.Writer) error {
+		rec := records[i]
+		return w.Write([]string{rec.AccountUUID, rec.ProductUUID, strconv.Itoa(rec.Quantity),
+			strconv.FormatFloat(rec.TotalAmount, 'f', 2, 64), strconv.FormatInt(rec.BuyingEpoch, 10)})
+	})
+}
+
+// loadInfileTable encodes nRows rows (via writeRow) as CSV, registers them
+// as a named reader, and issues LOAD DATA LOCAL INFILE against table. The
+// reader name is namespaced by shard as well as seq, since
+// RegisterReaderHandler's name table is process-global and multiple shards
+// flush concurrently.
+func loadInfileTable(ctx context.Context, db *sql.DB, table string, cols []string, shard int, seq uint64, nRows int, writeRow func(i int, w *csv.Writer) error) error {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	for i := 0; i < nRows; i++ {
+		if err := writeRow(i, cw); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("mysqldbgen-%s-shard%d-%d", table, shard, seq)
+	mysql.RegisterReaderHandler(name, func() io.Reader { return bytes.NewReader(buf.Bytes()) })
+	defer mysql.DeregisterReaderHandler(name)
+
+	query := fmt.Sprintf("LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '\"' LINES TERMINATED BY '\\n' (%s)",
+		name, table, strings.Join(cols, ","))
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
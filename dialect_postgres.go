@@ -0,0 +1,106 @@
+//go:build !nopostgres
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+func init() {
+	registerDialect("postgres", func() Dialect { return postgresDialect{} })
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string       { return "postgres" }
+func (postgresDialect) DriverName() string { return "postgres" }
+
+func (postgresDialect) DSN(cfg Config, shard ShardConfig, dbname string) string {
+	if dbname == "" {
+		// Postgres always requires a database to connect to; "postgres" is
+		// the well-known maintenance database present on every server.
+		dbname = "postgres"
+	}
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable", shard.User, shard.Password, shard.Host, shard.Port, dbname)
+}
+
+func (postgresDialect) CreateDatabase(ctx context.Context, adminDB *sql.DB, name string) error {
+	var exists int
+	err := adminDB.QueryRowContext(ctx, "SELECT 1 FROM pg_database WHERE datname = $1", name).Scan(&exists)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = adminDB.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE %s", pq.QuoteIdentifier(name)))
+		return err
+	default:
+		return err
+	}
+}
+
+func (postgresDialect) SchemaDDL() []string {
+	return append(postgresTableDDL(), postgresIndexDDL()...)
+}
+
+func (postgresDialect) Placeholder(i int) string { return fmt.Sprintf("$%d", i) }
+
+func (postgresDialect) IsDupKey(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" // unique_violation
+}
+
+func (postgresDialect) IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "40001" // serialization_failure
+}
+
+// postgresTableDDL creates the tables with native UUID columns and
+// REFERENCES-based foreign keys; Postgres (unlike MySQL) doesn't allow
+// INDEX clauses inline in CREATE TABLE, so indexes live in postgresIndexDDL.
+func postgresTableDDL() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS accounts (
+			a_uuid UUID PRIMARY KEY,
+			a_username VARCHAR(64) NOT NULL,
+			a_email VARCHAR(255) NOT NULL,
+			a_password VARCHAR(128) NOT NULL,
+			a_created_epoch BIGINT NOT NULL,
+			a_last_login_epoch BIGINT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS products (
+			pr_uuid UUID PRIMARY KEY,
+			pr_name VARCHAR(255) NOT NULL,
+			pr_authors VARCHAR(512) NOT NULL,
+			pr_price DECIMAL(10,2) NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS payments (
+			p_md5 CHAR(32) PRIMARY KEY,
+			p_amount DECIMAL(10,2) NOT NULL,
+			p_epoch BIGINT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS buying_stats (
+			bs_account_uuid UUID NOT NULL REFERENCES accounts(a_uuid) ON DELETE CASCADE,
+			bs_product_uuid UUID NOT NULL REFERENCES products(pr_uuid) ON DELETE CASCADE,
+			bs_quantity INT NOT NULL,
+			bs_total_amount DECIMAL(10,2) NOT NULL,
+			bs_epoch BIGINT NOT NULL
+		)`,
+	}
+}
+
+func postgresIndexDDL() []string {
+	return []string{
+		`CREATE INDEX IF NOT EXISTS idx_accounts_email ON accounts (a_email)`,
+		`CREATE INDEX IF NOT EXISTS idx_accounts_created ON accounts (a_created_epoch)`,
+		`CREATE INDEX IF NOT EXISTS idx_products_price ON products (pr_price)`,
+		`CREATE INDEX IF NOT EXISTS idx_payments_epoch ON payments (p_epoch)`,
+		`CREATE INDEX IF NOT EXISTS idx_bs_epoch ON buying_stats (bs_epoch)`,
+		`CREATE INDEX IF NOT EXISTS idx_bs_account ON buying_stats (bs_account_uuid)`,
+		`CREATE INDEX IF NOT EXISTS idx_bs_product ON buying_stats (bs_product_uuid)`,
+	}
+}
@@ -0,0 +1,156 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// dbImage is one entry in the integration matrix: a MySQL-family image/tag
+// to run loadData against end-to-end.
+type dbImage struct {
+	name       string
+	repository string
+	tag        string
+	env        []string
+}
+
+var integrationImages = []dbImage{
+	{name: "mysql-8.0", repository: "mysql", tag: "8.0", env: []string{"MYSQL_ROOT_PASSWORD=root"}},
+	{name: "mysql-5.7", repository: "mysql", tag: "5.7", env: []string{"MYSQL_ROOT_PASSWORD=root"}},
+	{name: "mariadb-10.6", repository: "mariadb", tag: "10.6", env: []string{"MARIADB_ROOT_PASSWORD=root", "MYSQL_ROOT_PASSWORD=root"}},
+}
+
+// TestIntegration_LoadData runs ensureDatabaseAndSchema + loadData against a
+// real server for each image in integrationImages, then asserts exact row
+// counts and buying_stats FK integrity.
+func TestIntegration_LoadData(t *testing.T) {
+	for _, img := range integrationImages {
+		img := img
+		t.Run(img.name, func(t *testing.T) {
+			t.Parallel()
+			runIntegrationLoad(t, img)
+		})
+	}
+}
+
+func runIntegrationLoad(t *testing.T, img dbImage) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		t.Fatalf("dockertest.NewPool: %v", err)
+	}
+	pool.MaxWait = 120 * time.Second
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: img.repository,
+		Tag:        img.tag,
+		Env:        img.env,
+	}, func(hc *docker.HostConfig) {
+		hc.AutoRemove = true
+		hc.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		t.Fatalf("start %s:%s: %v", img.repository, img.tag, err)
+	}
+	t.Cleanup(func() { _ = pool.Purge(resource) })
+
+	port, err := atoiPort(resource.GetPort("3306/tcp"))
+	if err != nil {
+		t.Fatalf("parse container port: %v", err)
+	}
+
+	cfg := defaultConfig()
+	cfg.Host = "localhost"
+	cfg.Port = port
+	cfg.User = "root"
+	cfg.Password = "root"
+	cfg.DBName = "mysqldbgen_integration"
+	cfg.NumWorkers = 2
+	cfg.DBRecordsToLoad = 40
+	cfg.NumAccounts = 10
+	cfg.NumProducts = 5
+	cfg.NumPayments = 10
+	cfg.Loader = loaderBatch
+	cfg.BatchSize = 10
+
+	dialect, err := dialectFor("mysql")
+	if err != nil {
+		t.Fatalf("dialectFor: %v", err)
+	}
+	shard := resolveShards(cfg)[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if err := pool.Retry(func() error {
+		db, err := sql.Open(dialect.DriverName(), dialect.DSN(cfg, shard, ""))
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return db.PingContext(ctx)
+	}); err != nil {
+		t.Fatalf("%s:%s never became ready: %v", img.repository, img.tag, err)
+	}
+
+	if err := ensureDatabaseAndSchema(ctx, cfg, dialect); err != nil {
+		t.Fatalf("ensureDatabaseAndSchema: %v", err)
+	}
+	if err := loadData(ctx, cfg, dialect); err != nil {
+		t.Fatalf("loadData: %v", err)
+	}
+
+	db, err := sql.Open(dialect.DriverName(), dialect.DSN(cfg, shard, cfg.DBName))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	assertCount(t, ctx, db, "accounts", cfg.NumAccounts)
+	assertCount(t, ctx, db, "products", cfg.NumProducts)
+	assertCount(t, ctx, db, "payments", cfg.NumPayments)
+	assertCount(t, ctx, db, "buying_stats", cfg.DBRecordsToLoad)
+
+	assertNoOrphans(t, ctx, db, "bs_account_uuid", "accounts", "a_uuid")
+	assertNoOrphans(t, ctx, db, "bs_product_uuid", "products", "pr_uuid")
+}
+
+func assertCount(t *testing.T, ctx context.Context, db *sql.DB, table string, want int) {
+	t.Helper()
+	var got int
+	if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", table)).Scan(&got); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	if got != want {
+		t.Fatalf("%s: got %d rows, want %d", table, got, want)
+	}
+}
+
+// assertNoOrphans fails the test if any buying_stats row's fkCol has no
+// matching row in refTable, e.g. a dangling bs_account_uuid.
+func assertNoOrphans(t *testing.T, ctx context.Context, db *sql.DB, fkCol, refTable, refCol string) {
+	t.Helper()
+	q := fmt.Sprintf(
+		"SELECT COUNT(*) FROM buying_stats bs LEFT JOIN %s r ON bs.%s = r.%s WHERE r.%s IS NULL",
+		refTable, fkCol, refCol, refCol)
+	var orphans int
+	if err := db.QueryRowContext(ctx, q).Scan(&orphans); err != nil {
+		t.Fatalf("orphan check (%s): %v", refTable, err)
+	}
+	if orphans != 0 {
+		t.Fatalf("%s: %d buying_stats rows have no matching %s", refTable, orphans, refTable)
+	}
+}
+
+func atoiPort(s string) (int, error) {
+	var p int
+	_, err := fmt.Sscanf(s, "%d", &p)
+	return p, err
+}
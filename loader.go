@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Loader selects how generated records are turned into SQL statements.
+const (
+	loaderTx     = "tx"     // one BEGIN/INSERT x4/COMMIT per record
+	loaderBatch  = "batch"  // multi-row INSERT per table, batchSize records per flush
+	loaderInfile = "infile" // LOAD DATA LOCAL INFILE per table, batchSize records per flush
+)
+
+var (
+	accountCols = []string{"a_uuid", "a_username", "a_email", "a_password", "a_created_epoch", "a_last_login_epoch"}
+	productCols = []string{"pr_uuid", "pr_name", "pr_authors", "pr_price"}
+	paymentCols = []string{"p_md5", "p_amount", "p_epoch"}
+	buyingCols  = []string{"bs_account_uuid", "bs_product_uuid", "bs_quantity", "bs_total_amount", "bs_epoch"}
+)
+
+// Rough upper bounds on a single row's encoded size, used to keep a batch
+// INSERT statement under maxPacketBytes. They don't need to be exact, only
+// conservative enough that we split before MySQL rejects the packet.
+const (
+	estBytesAccountRow = 600
+	estBytesProductRow = 850
+	estBytesPaymentRow = 100
+	estBytesBuyingRow  = 150
+)
+
+// loadDataBatch accumulates batchSize generated buying_stats rows per
+// worker per shard and issues one multi-row INSERT per shard transaction
+// instead of one row at a time, which cuts round trips dramatically at the
+// cost of losing a whole flush (instead of a single row) on a duplicate key.
+func loadDataBatch(ctx context.Context, cfg Config, shardDBs []*sql.DB, dialect Dialect, accounts []Account, products []Product) error {
+	nShards := len(shardDBs)
+	var inserted uint64
+	shardInserted := make([]uint64, nShards)
+	recordsPerLog := recordsPerLog(cfg.DBRecordsToLoad, cfg.PcentOutput)
+	start := time.Now()
+	retryBaseBackoff := time.Duration(cfg.RetryBaseBackoffMS) * time.Millisecond
+
+	jobs := make(chan int, cfg.NumWorkers*4)
+	var wg sync.WaitGroup
+	errCh := make(chan error, cfg.NumWorkers)
+
+	for w := 0; w < cfg.NumWorkers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(time.Now().UnixNano() + int64(workerID*1000)))
+			acctSampler := newSampler(r, cfg, len(accounts))
+			prodSampler := newSampler(r, cfg, len(products))
+			bufs := make([][]BuyingStat, nShards)
+			for s := range bufs {
+				bufs[s] = make([]BuyingStat, 0, cfg.BatchSize)
+			}
+
+			flush := func(s int) bool {
+				if len(bufs[s]) == 0 {
+					return true
+				}
+				err := executeTx(ctx, shardDBs[s], dialect, cfg.MaxRetries, retryBaseBackoff, func(tx *sql.Tx) error {
+					return insertBuyingStats(ctx, tx, dialect, cfg.MaxPacketBytes, bufs[s])
+				})
+				if err != nil {
+					if dialect.IsDupKey(err) {
+						log.Printf("shard %d: batch dropped (%d records): %v", s, len(bufs[s]), err)
+						bufs[s] = bufs[s][:0]
+						return true
+					}
+					errCh <- err
+					return false
+				}
+
+				n := atomic.AddUint64(&inserted, uint64(len(bufs[s])))
+				sn := atomic.AddUint64(&shardInserted[s], uint64(len(bufs[s])))
+				if recordsPerLog > 0 {
+					for logged := n - uint64(len(bufs[s])) + 1; logged <= n; logged++ {
+						if int(logged)%recordsPerLog == 0 {
+							logShardProgress(cfg.Loader, s, sn, logged, cfg.DBRecordsToLoad, start)
+						}
+					}
+				}
+				bufs[s] = bufs[s][:0]
+				return int(n) < cfg.DBRecordsToLoad
+			}
+
+			for idx := range jobs {
+				bs := generateBuyingStat(r, accounts, products, acctSampler, prodSampler)
+				s := shardIndex(cfg, shardKeyValue(cfg, bs), idx, cfg.DBRecordsToLoad, nShards)
+				bufs[s] = append(bufs[s], bs)
+				if len(bufs[s]) >= cfg.BatchSize {
+					if !flush(s) {
+						return
+					}
+				}
+			}
+			for s := range bufs {
+				if !flush(s) {
+					return
+				}
+			}
+		}(w)
+	}
+
+	if err := feedJobs(jobs, cfg.DBRecordsToLoad, errCh); err != nil {
+		return err
+	}
+
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return err
+	default:
+	}
+
+	final := atomic.LoadUint64(&inserted)
+	if int(final) < cfg.DBRecordsToLoad {
+		return fmt.Errorf("only inserted %d/%d records (duplicate keys likely); try increasing dbRecords2Process", final, cfg.DBRecordsToLoad)
+	}
+
+	logFinal(cfg.Loader, final, start)
+	logShardTotals(shardInserted)
+	return nil
+}
+
+// insertBuyingStats writes one flush's worth of buying_stats rows, splitting
+// the multi-row INSERT into packet-sized chunks.
+func insertBuyingStats(ctx context.Context, tx *sql.Tx, dialect Dialect, maxPacketBytes int, records []BuyingStat) error {
+	bsRows := make([][]any, len(records))
+	for i, rec := range records {
+		bsRows[i] = []any{rec.AccountUUID, rec.ProductUUID, rec.Quantity, rec.TotalAmount, rec.BuyingEpoch}
+	}
+	return insertRowsChunked(ctx, tx, dialect, "buying_stats", buyingCols, estBytesBuyingRow, maxPacketBytes, bsRows)
+}
+
+// insertRowsChunked issues one or more multi-row INSERT statements for rows,
+// keeping each statement's estimated size under maxPacketBytes.
+func insertRowsChunked(ctx context.Context, tx *sql.Tx, dialect Dialect, table string, cols []string, estBytesPerRow, maxPacketBytes int, rows [][]any) error {
+	for len(rows) > 0 {
+		n := chunkSize(maxPacketBytes, estBytesPerRow, len(rows))
+		chunk := rows[:n]
+		args := make([]any, 0, n*len(cols))
+		for _, row := range chunk {
+			args = append(args, row...)
+		}
+		if _, err := tx.ExecContext(ctx, buildMultiInsertSQL(dialect, table, cols, n), args...); err != nil {
+			return err
+		}
+		rows = rows[n:]
+	}
+	return nil
+}
+
+// chunkSize returns how many rows of estBytesPerRow fit under
+// maxPacketBytes, clamped to [1, total].
+func chunkSize(maxPacketBytes, estBytesPerRow, total int) int {
+	n := maxPacketBytes / estBytesPerRow
+	if n < 1 {
+		n = 1
+	}
+	if n > total {
+		n = total
+	}
+	return n
+}
+
+// loadDataInfile dispatches to the mysql-only LOAD DATA LOCAL INFILE
+// implementation registered by dialect_mysql_infile.go. It's a separate
+// build-tag-gated file because the infile path uses go-sql-driver/mysql's
+// RegisterReaderHandler directly, which has no Postgres/Cockroach analogue.
+func loadDataInfile(ctx context.Context, cfg Config, shardDBs []*sql.DB, dialect Dialect, accounts []Account, products []Product) error {
+	if infileLoader == nil {
+		return fmt.Errorf("loader=infile is not available in this build (requires the mysql/mariadb dialect)")
+	}
+	return infileLoader(ctx, cfg, shardDBs, dialect, accounts, products)
+}
+
+// infileLoader is set by dialect_mysql_infile.go's init() when that
+// (default-on) build tag is included; nil otherwise.
+var infileLoader func(ctx context.Context, cfg Config, shardDBs []*sql.DB, dialect Dialect, accounts []Account, products []Product) error
+
+// feedJobs pushes indices [0, total) onto jobs, bailing out early if a
+// worker has already reported a fatal error, then closes jobs.
+func feedJobs(jobs chan<- int, total int, errCh <-chan error) error {
+	defer close(jobs)
+	for i := 0; i < total; i++ {
+		select {
+		case err := <-errCh:
+			return err
+		default:
+		}
+		jobs <- i
+	}
+	return nil
+}
+
+// logFinal reports the final throughput for a completed load.
+func logFinal(mode string, n uint64, start time.Time) {
+	elapsed := time.Since(start)
+	rps := float64(n) / math.Max(elapsed.Seconds(), 0.001)
+	log.Printf("inserted %d records in %s (%.0f rec/s) [loader=%s]", n, elapsed.Truncate(time.Millisecond), rps, mode)
+}
+
+// logShardProgress reports loader throughput so users can compare
+// tx/batch/infile modes, plus which shard the just-inserted batch landed on
+// and that shard's running total, so users can spot skew across shards
+// while a sharded load is still in flight.
+func logShardProgress(mode string, shard int, shardN, n uint64, total int, start time.Time) {
+	elapsed := time.Since(start)
+	rps := float64(n) / math.Max(elapsed.Seconds(), 0.001)
+	log.Printf("progress[%s]: %d/%d (%.1f%%) rate=%.0f rec/s elapsed=%s shard=%d shard_total=%d",
+		mode, n, total, 100*float64(n)/float64(total), rps, elapsed.Truncate(time.Millisecond), shard, shardN)
+}
+
+// logShardTotals reports each shard's final row count, so skew is visible
+// at a glance once a sharded load completes.
+func logShardTotals(shardInserted []uint64) {
+	for s, n := range shardInserted {
+		log.Printf("shard %d: inserted %d records", s, n)
+	}
+}
@@ -2,9 +2,7 @@ package main
 
 import (
 	"context"
-	"crypto/md5"
 	"database/sql"
-	"encoding/hex"
 	"flag"
 	"fmt"
 	"log"
@@ -17,41 +15,66 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/go-faker/faker/v4"
-	_ "github.com/go-sql-driver/mysql"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Host            string `yaml:"host"`
-	Port            int    `yaml:"port"`
-	User            string `yaml:"user"`
-	Password        string `yaml:"password"`
-	DBName          string `yaml:"dbname"`
-	ConfigPath      string `yaml:"-"`
-	RunOnlyFaker    bool   `yaml:"runOnlyFaker"`
-	NumWorkers      int    `yaml:"numWorkers"`
-	DBRecordsToLoad int    `yaml:"dbRecords2Process"`
-	PcentOutput     int    `yaml:"pcentOutput"`
-	MinDays         int64  `yaml:"minDays"`
-	MaxDays         int64  `yaml:"maxDays"`
-	DelayLastLogin  int64  `yaml:"delayLastLogin"`
+	Host               string        `yaml:"host"`
+	Port               int           `yaml:"port"`
+	User               string        `yaml:"user"`
+	Password           string        `yaml:"password"`
+	DBName             string        `yaml:"dbname"`
+	ConfigPath         string        `yaml:"-"`
+	RunOnlyFaker       bool          `yaml:"runOnlyFaker"`
+	NumWorkers         int           `yaml:"numWorkers"`
+	DBRecordsToLoad    int           `yaml:"dbRecords2Process"`
+	PcentOutput        int           `yaml:"pcentOutput"`
+	MinDays            int64         `yaml:"minDays"`
+	MaxDays            int64         `yaml:"maxDays"`
+	DelayLastLogin     int64         `yaml:"delayLastLogin"`
+	MaxRetries         int           `yaml:"maxRetries"`
+	RetryBaseBackoffMS int64         `yaml:"retryBaseBackoffMs"`
+	Loader             string        `yaml:"loader"`
+	BatchSize          int           `yaml:"batchSize"`
+	MaxPacketBytes     int           `yaml:"maxPacketBytes"`
+	Driver             string        `yaml:"driver"`
+	NumAccounts        int           `yaml:"numAccounts"`
+	NumProducts        int           `yaml:"numProducts"`
+	NumPayments        int           `yaml:"numPayments"`
+	Distribution       string        `yaml:"distribution"`
+	ZipfExponent       float64       `yaml:"zipfExponent"`
+	Shards             []ShardConfig `yaml:"shards"`
+	ShardKey           string        `yaml:"shardKey"`
+	ShardStrategy      string        `yaml:"shardStrategy"`
 }
 
 func defaultConfig() Config {
 	return Config{
-		Host:            "localhost",
-		Port:            3306,
-		User:            "root",
-		Password:        "root",
-		DBName:          "mytestdb",
-		RunOnlyFaker:    false,
-		NumWorkers:      3,
-		DBRecordsToLoad: 100,
-		PcentOutput:     10,
-		MinDays:         3 * 24 * 60 * 60,
-		MaxDays:         365 * 24 * 60 * 60,
-		DelayLastLogin:  500,
+		Host:               "localhost",
+		Port:               3306,
+		User:               "root",
+		Password:           "root",
+		DBName:             "mytestdb",
+		RunOnlyFaker:       false,
+		NumWorkers:         3,
+		DBRecordsToLoad:    100,
+		PcentOutput:        10,
+		MinDays:            3 * 24 * 60 * 60,
+		MaxDays:            365 * 24 * 60 * 60,
+		DelayLastLogin:     500,
+		MaxRetries:         5,
+		RetryBaseBackoffMS: 50,
+		Loader:             loaderTx,
+		BatchSize:          200,
+		MaxPacketBytes:     4 * 1024 * 1024,
+		Driver:             "mysql",
+		NumAccounts:        1000,
+		NumProducts:        200,
+		NumPayments:        1000,
+		Distribution:       distributionUniform,
+		ZipfExponent:       1.5,
+		ShardKey:           shardKeyAccount,
+		ShardStrategy:      shardStrategyHash,
 	}
 }
 
@@ -81,6 +104,19 @@ func main() {
 	fs.Int64Var(&cfg.MinDays, "minDays", cfg.MinDays, "minimum account created offset in seconds")
 	fs.Int64Var(&cfg.MaxDays, "maxDays", cfg.MaxDays, "maximum account created offset in seconds")
 	fs.Int64Var(&cfg.DelayLastLogin, "delayLastLogin", cfg.DelayLastLogin, "random last-login delay in seconds")
+	fs.IntVar(&cfg.MaxRetries, "maxRetries", cfg.MaxRetries, "max retries for a deadlock/lock-wait-timeout transaction")
+	fs.Int64Var(&cfg.RetryBaseBackoffMS, "retryBaseBackoffMs", cfg.RetryBaseBackoffMS, "base backoff in milliseconds before a transaction retry")
+	fs.StringVar(&cfg.Loader, "loader", cfg.Loader, "insert strategy: tx (per-record), batch (multi-row INSERT), or infile (LOAD DATA LOCAL INFILE)")
+	fs.IntVar(&cfg.BatchSize, "batchSize", cfg.BatchSize, "records accumulated per flush in batch/infile loader modes")
+	fs.IntVar(&cfg.MaxPacketBytes, "maxPacketBytes", cfg.MaxPacketBytes, "approximate cap on a single batch INSERT statement's size, should track MySQL's max_allowed_packet")
+	fs.StringVar(&cfg.Driver, "driver", cfg.Driver, "backend: mysql, mariadb, postgres, or cockroach")
+	fs.IntVar(&cfg.NumAccounts, "numAccounts", cfg.NumAccounts, "size of the pre-generated account pool")
+	fs.IntVar(&cfg.NumProducts, "numProducts", cfg.NumProducts, "size of the pre-generated product pool")
+	fs.IntVar(&cfg.NumPayments, "numPayments", cfg.NumPayments, "number of standalone payment rows to generate")
+	fs.StringVar(&cfg.Distribution, "distribution", cfg.Distribution, "buying_stats account/product sampling: uniform or zipf")
+	fs.Float64Var(&cfg.ZipfExponent, "zipfExponent", cfg.ZipfExponent, "Zipf distribution exponent (>1.0) used when distribution=zipf; higher concentrates sampling on fewer accounts/products")
+	fs.StringVar(&cfg.ShardKey, "shardKey", cfg.ShardKey, "buying_stats column used to route rows to a shard: account_uuid or product_uuid")
+	fs.StringVar(&cfg.ShardStrategy, "shardStrategy", cfg.ShardStrategy, "shard routing strategy: hash, range, or round-robin")
 	_ = fs.Parse(os.Args[1:])
 
 	if cfg.DBRecordsToLoad < 1 {
@@ -95,9 +131,61 @@ func main() {
 	if cfg.MaxDays < cfg.MinDays {
 		log.Fatalf("maxDays must be >= minDays")
 	}
+	if cfg.MaxRetries < 0 {
+		log.Fatalf("maxRetries must be >= 0")
+	}
+	if cfg.RetryBaseBackoffMS < 1 {
+		log.Fatalf("retryBaseBackoffMs must be >= 1")
+	}
+	switch cfg.Loader {
+	case loaderTx, loaderBatch, loaderInfile:
+	default:
+		log.Fatalf("loader must be one of tx, batch, infile (got %q)", cfg.Loader)
+	}
+	if cfg.BatchSize < 1 {
+		log.Fatalf("batchSize must be >= 1")
+	}
+	if cfg.MaxPacketBytes < 1 {
+		log.Fatalf("maxPacketBytes must be >= 1")
+	}
+	if cfg.NumAccounts < 1 {
+		log.Fatalf("numAccounts must be >= 1")
+	}
+	if cfg.NumProducts < 1 {
+		log.Fatalf("numProducts must be >= 1")
+	}
+	if cfg.NumPayments < 1 {
+		log.Fatalf("numPayments must be >= 1")
+	}
+	switch cfg.Distribution {
+	case distributionUniform, distributionZipf:
+	default:
+		log.Fatalf("distribution must be one of uniform, zipf (got %q)", cfg.Distribution)
+	}
+	if cfg.Distribution == distributionZipf && cfg.ZipfExponent <= 1.0 {
+		log.Fatalf("zipfExponent must be > 1.0")
+	}
+	switch cfg.ShardKey {
+	case shardKeyAccount, shardKeyProduct:
+	default:
+		log.Fatalf("shardKey must be one of account_uuid, product_uuid (got %q)", cfg.ShardKey)
+	}
+	switch cfg.ShardStrategy {
+	case shardStrategyHash, shardStrategyRange, shardStrategyRoundRobin:
+	default:
+		log.Fatalf("shardStrategy must be one of hash, range, round-robin (got %q)", cfg.ShardStrategy)
+	}
+	dialect, err := dialectFor(cfg.Driver)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if cfg.Loader == loaderInfile && dialect.DriverName() != "mysql" {
+		log.Fatalf("loader=infile is only supported by the mysql/mariadb driver")
+	}
 
-	log.Printf("mysqldbgen: host=%s port=%d user=%s dbname=%s workers=%d records=%d config=%q runOnlyFaker=%v",
-		cfg.Host, cfg.Port, cfg.User, cfg.DBName, cfg.NumWorkers, cfg.DBRecordsToLoad, cfg.ConfigPath, cfg.RunOnlyFaker)
+	shards := resolveShards(cfg)
+	log.Printf("mysqldbgen: driver=%s shards=%d shardKey=%s shardStrategy=%s workers=%d records=%d loader=%s config=%q runOnlyFaker=%v",
+		dialect.Name(), len(shards), cfg.ShardKey, cfg.ShardStrategy, cfg.NumWorkers, cfg.DBRecordsToLoad, cfg.Loader, cfg.ConfigPath, cfg.RunOnlyFaker)
 
 	ctx := context.Background()
 	if cfg.RunOnlyFaker {
@@ -105,10 +193,10 @@ func main() {
 		return
 	}
 
-	if err := ensureDatabaseAndSchema(ctx, cfg); err != nil {
+	if err := ensureDatabaseAndSchema(ctx, cfg, dialect); err != nil {
 		log.Fatalf("setup failed: %v", err)
 	}
-	if err := loadData(ctx, cfg); err != nil {
+	if err := loadData(ctx, cfg, dialect); err != nil {
 		log.Fatalf("load failed: %v", err)
 	}
 	log.Printf("done")
@@ -141,9 +229,34 @@ func loadYAML(path string, out *Config) error {
 	return yaml.Unmarshal(b, out)
 }
 
-func ensureDatabaseAndSchema(ctx context.Context, cfg Config) error {
+// ensureDatabaseAndSchema creates the database and schema on every shard in
+// parallel, since accounts and products are replicated to all shards (so
+// that a buying_stats row can always satisfy its foreign keys locally,
+// wherever it gets routed).
+func ensureDatabaseAndSchema(ctx context.Context, cfg Config, dialect Dialect) error {
+	shards := resolveShards(cfg)
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(shards))
+	for i, shard := range shards {
+		wg.Add(1)
+		go func(i int, shard ShardConfig) {
+			defer wg.Done()
+			if err := ensureShardDatabaseAndSchema(ctx, cfg, shard, dialect); err != nil {
+				errCh <- fmt.Errorf("shard %d (%s:%d): %w", i, shard.Host, shard.Port, err)
+			}
+		}(i, shard)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		return err
+	}
+	return nil
+}
+
+func ensureShardDatabaseAndSchema(ctx context.Context, cfg Config, shard ShardConfig, dialect Dialect) error {
 	// Connect without selecting a DB so we can create it if missing.
-	adminDB, err := sql.Open("mysql", dsn(cfg, ""))
+	adminDB, err := sql.Open(dialect.DriverName(), dialect.DSN(cfg, shard, ""))
 	if err != nil {
 		return err
 	}
@@ -157,11 +270,11 @@ func ensureDatabaseAndSchema(ctx context.Context, cfg Config) error {
 		return err
 	}
 
-	if _, err := adminDB.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", cfg.DBName)); err != nil {
+	if err := dialect.CreateDatabase(ctx, adminDB, shard.DBName); err != nil {
 		return fmt.Errorf("create database: %w", err)
 	}
 
-	db, err := sql.Open("mysql", dsn(cfg, cfg.DBName))
+	db, err := sql.Open(dialect.DriverName(), dialect.DSN(cfg, shard, shard.DBName))
 	if err != nil {
 		return err
 	}
@@ -170,45 +283,7 @@ func ensureDatabaseAndSchema(ctx context.Context, cfg Config) error {
 		return err
 	}
 
-	stmts := []string{
-		`CREATE TABLE IF NOT EXISTS accounts (
-			a_uuid CHAR(36) PRIMARY KEY,
-			a_username VARCHAR(64) NOT NULL,
-			a_email VARCHAR(255) NOT NULL,
-			a_password VARCHAR(128) NOT NULL,
-			a_created_epoch BIGINT NOT NULL,
-			a_last_login_epoch BIGINT NOT NULL,
-			INDEX idx_accounts_email (a_email),
-			INDEX idx_accounts_created (a_created_epoch)
-		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
-		`CREATE TABLE IF NOT EXISTS products (
-			pr_uuid CHAR(36) PRIMARY KEY,
-			pr_name VARCHAR(255) NOT NULL,
-			pr_authors VARCHAR(512) NOT NULL,
-			pr_price DECIMAL(10,2) NOT NULL,
-			INDEX idx_products_price (pr_price)
-		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
-		`CREATE TABLE IF NOT EXISTS payments (
-			p_md5 CHAR(32) PRIMARY KEY,
-			p_amount DECIMAL(10,2) NOT NULL,
-			p_epoch BIGINT NOT NULL,
-			INDEX idx_payments_epoch (p_epoch)
-		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
-		`CREATE TABLE IF NOT EXISTS buying_stats (
-			bs_account_uuid CHAR(36) NOT NULL,
-			bs_product_uuid CHAR(36) NOT NULL,
-			bs_quantity INT NOT NULL,
-			bs_total_amount DECIMAL(10,2) NOT NULL,
-			bs_epoch BIGINT NOT NULL,
-			INDEX idx_bs_epoch (bs_epoch),
-			INDEX idx_bs_account (bs_account_uuid),
-			INDEX idx_bs_product (bs_product_uuid),
-			CONSTRAINT fk_bs_account FOREIGN KEY (bs_account_uuid) REFERENCES accounts(a_uuid) ON DELETE CASCADE,
-			CONSTRAINT fk_bs_product FOREIGN KEY (bs_product_uuid) REFERENCES products(pr_uuid) ON DELETE CASCADE
-		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
-	}
-
-	for _, s := range stmts {
+	for _, s := range dialect.SchemaDDL() {
 		if _, err := db.ExecContext(ctx, s); err != nil {
 			return fmt.Errorf("create tables: %w", err)
 		}
@@ -216,52 +291,108 @@ func ensureDatabaseAndSchema(ctx context.Context, cfg Config) error {
 	return nil
 }
 
-func loadData(ctx context.Context, cfg Config) error {
-	db, err := sql.Open("mysql", dsn(cfg, cfg.DBName))
-	if err != nil {
-		return err
-	}
-	defer db.Close()
+// loadData runs the three-phase load: (1) pre-generate the account and
+// product pools and replicate them to every shard, (2) pre-generate and
+// replicate standalone payments, then (3) generate dbRecords2Process
+// buying_stats rows, routing each to shards[shardIndex(...)] so its foreign
+// keys always resolve on the shard it lands on, via whichever cfg.Loader
+// strategy was selected.
+func loadData(ctx context.Context, cfg Config, dialect Dialect) error {
+	shards := resolveShards(cfg)
+	shardDBs := make([]*sql.DB, len(shards))
+	for i, shard := range shards {
+		db, err := sql.Open(dialect.DriverName(), dialect.DSN(cfg, shard, shard.DBName))
+		if err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+		defer db.Close()
 
-	db.SetConnMaxLifetime(10 * time.Minute)
-	db.SetMaxOpenConns(max(4, cfg.NumWorkers*2))
-	db.SetMaxIdleConns(max(4, cfg.NumWorkers*2))
+		db.SetConnMaxLifetime(10 * time.Minute)
+		db.SetMaxOpenConns(max(4, cfg.NumWorkers*2))
+		db.SetMaxIdleConns(max(4, cfg.NumWorkers*2))
 
-	if err := db.PingContext(ctx); err != nil {
-		return err
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+		shardDBs[i] = db
 	}
 
-	insAcct, err := db.PrepareContext(ctx, `INSERT INTO accounts (a_uuid,a_username,a_email,a_password,a_created_epoch,a_last_login_epoch)
-		VALUES (?,?,?,?,?,?)`)
-	if err != nil {
-		return err
-	}
-	defer insAcct.Close()
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	insProd, err := db.PrepareContext(ctx, `INSERT INTO products (pr_uuid,pr_name,pr_authors,pr_price)
-		VALUES (?,?,?,?)`)
-	if err != nil {
-		return err
+	log.Printf("phase 1: generating %d accounts and %d products, replicating to %d shard(s)", cfg.NumAccounts, cfg.NumProducts, len(shardDBs))
+	accounts := generateAccounts(r, cfg, cfg.NumAccounts)
+	products := generateProducts(r, cfg, cfg.NumProducts)
+	if err := insertToAllShards(ctx, shardDBs, dialect, cfg, func(db *sql.DB) error {
+		return insertAccounts(ctx, db, dialect, cfg, accounts)
+	}); err != nil {
+		return fmt.Errorf("phase 1 (accounts): %w", err)
+	}
+	if err := insertToAllShards(ctx, shardDBs, dialect, cfg, func(db *sql.DB) error {
+		return insertProducts(ctx, db, dialect, cfg, products)
+	}); err != nil {
+		return fmt.Errorf("phase 1 (products): %w", err)
+	}
+
+	log.Printf("phase 2: generating %d payments, replicating to %d shard(s)", cfg.NumPayments, len(shardDBs))
+	payments := generatePayments(r, cfg, cfg.NumPayments)
+	if err := insertToAllShards(ctx, shardDBs, dialect, cfg, func(db *sql.DB) error {
+		return insertPayments(ctx, db, dialect, cfg, payments)
+	}); err != nil {
+		return fmt.Errorf("phase 2 (payments): %w", err)
+	}
+
+	log.Printf("phase 3: generating %d buying_stats rows (distribution=%s, shardKey=%s, shardStrategy=%s) via loader=%s",
+		cfg.DBRecordsToLoad, cfg.Distribution, cfg.ShardKey, cfg.ShardStrategy, cfg.Loader)
+	switch cfg.Loader {
+	case loaderBatch:
+		return loadDataBatch(ctx, cfg, shardDBs, dialect, accounts, products)
+	case loaderInfile:
+		return loadDataInfile(ctx, cfg, shardDBs, dialect, accounts, products)
+	default:
+		return loadDataTx(ctx, cfg, shardDBs, dialect, accounts, products)
 	}
-	defer insProd.Close()
+}
 
-	insPay, err := db.PrepareContext(ctx, `INSERT INTO payments (p_md5,p_amount,p_epoch)
-		VALUES (?,?,?)`)
-	if err != nil {
+// insertToAllShards runs insert against every shard's *sql.DB in parallel.
+func insertToAllShards(ctx context.Context, shardDBs []*sql.DB, dialect Dialect, cfg Config, insert func(db *sql.DB) error) error {
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(shardDBs))
+	for i, db := range shardDBs {
+		wg.Add(1)
+		go func(i int, db *sql.DB) {
+			defer wg.Done()
+			if err := insert(db); err != nil {
+				errCh <- fmt.Errorf("shard %d: %w", i, err)
+			}
+		}(i, db)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
 		return err
 	}
-	defer insPay.Close()
+	return nil
+}
 
-	insBS, err := db.PrepareContext(ctx, `INSERT INTO buying_stats (bs_account_uuid,bs_product_uuid,bs_quantity,bs_total_amount,bs_epoch)
-		VALUES (?,?,?,?,?)`)
-	if err != nil {
-		return err
+// loadDataTx is the original insert strategy: one BEGIN/INSERT/COMMIT per
+// generated buying_stats row, retried via executeTx on transient errors.
+func loadDataTx(ctx context.Context, cfg Config, shardDBs []*sql.DB, dialect Dialect, accounts []Account, products []Product) error {
+	nShards := len(shardDBs)
+	insBS := make([]*sql.Stmt, nShards)
+	for i, db := range shardDBs {
+		stmt, err := db.PrepareContext(ctx, buildInsertSQL(dialect, "buying_stats", buyingCols))
+		if err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+		defer stmt.Close()
+		insBS[i] = stmt
 	}
-	defer insBS.Close()
 
 	var inserted uint64
+	shardInserted := make([]uint64, nShards)
 	recordsPerLog := recordsPerLog(cfg.DBRecordsToLoad, cfg.PcentOutput)
 	start := time.Now()
+	retryBaseBackoff := time.Duration(cfg.RetryBaseBackoffMS) * time.Millisecond
 
 	jobs := make(chan int, cfg.NumWorkers*4)
 	var wg sync.WaitGroup
@@ -272,66 +403,29 @@ func loadData(ctx context.Context, cfg Config) error {
 		go func(workerID int) {
 			defer wg.Done()
 			r := rand.New(rand.NewSource(time.Now().UnixNano() + int64(workerID*1000)))
-			for range jobs {
-				rec := generateRecord(r, cfg)
-
-				tx, err := db.BeginTx(ctx, nil)
+			acctSampler := newSampler(r, cfg, len(accounts))
+			prodSampler := newSampler(r, cfg, len(products))
+			for idx := range jobs {
+				bs := generateBuyingStat(r, accounts, products, acctSampler, prodSampler)
+				s := shardIndex(cfg, shardKeyValue(cfg, bs), idx, cfg.DBRecordsToLoad, nShards)
+
+				err := executeTx(ctx, shardDBs[s], dialect, cfg.MaxRetries, retryBaseBackoff, func(tx *sql.Tx) error {
+					_, err := tx.StmtContext(ctx, insBS[s]).ExecContext(ctx,
+						bs.AccountUUID, bs.ProductUUID, bs.Quantity, bs.TotalAmount, bs.BuyingEpoch)
+					return err
+				})
 				if err != nil {
-					errCh <- err
-					return
-				}
-
-				if _, err := tx.StmtContext(ctx, insAcct).ExecContext(ctx,
-					rec.AccountUUID, rec.Username, rec.Email, rec.Password, rec.CreatedEpoch, rec.LastLoginEpoch); err != nil {
-					_ = tx.Rollback()
-					if isDupKey(err) {
-						continue
-					}
-					errCh <- err
-					return
-				}
-
-				if _, err := tx.StmtContext(ctx, insProd).ExecContext(ctx,
-					rec.ProductUUID, rec.ProductName, rec.ProductAuthors, rec.ProductPrice); err != nil {
-					_ = tx.Rollback()
-					if isDupKey(err) {
-						continue
-					}
-					errCh <- err
-					return
-				}
-
-				if _, err := tx.StmtContext(ctx, insPay).ExecContext(ctx,
-					rec.PaymentMD5, rec.PaymentAmount, rec.PaymentEpoch); err != nil {
-					_ = tx.Rollback()
-					if isDupKey(err) {
+					if dialect.IsDupKey(err) {
 						continue
 					}
 					errCh <- err
 					return
 				}
 
-				if _, err := tx.StmtContext(ctx, insBS).ExecContext(ctx,
-					rec.AccountUUID, rec.ProductUUID, rec.Quantity, rec.TotalAmount, rec.BuyingEpoch); err != nil {
-					_ = tx.Rollback()
-					if isDupKey(err) {
-						continue
-					}
-					errCh <- err
-					return
-				}
-
-				if err := tx.Commit(); err != nil {
-					errCh <- err
-					return
-				}
-
 				n := atomic.AddUint64(&inserted, 1)
+				sn := atomic.AddUint64(&shardInserted[s], 1)
 				if recordsPerLog > 0 && int(n)%recordsPerLog == 0 {
-					elapsed := time.Since(start)
-					rps := float64(n) / math.Max(elapsed.Seconds(), 0.001)
-					log.Printf("progress: %d/%d (%.1f%%) rate=%.0f rec/s elapsed=%s",
-						n, cfg.DBRecordsToLoad, 100*float64(n)/float64(cfg.DBRecordsToLoad), rps, elapsed.Truncate(time.Millisecond))
+					logShardProgress(cfg.Loader, s, sn, n, cfg.DBRecordsToLoad, start)
 				}
 				if int(n) >= cfg.DBRecordsToLoad {
 					return
@@ -362,9 +456,8 @@ func loadData(ctx context.Context, cfg Config) error {
 		return fmt.Errorf("only inserted %d/%d records (duplicate keys likely); try increasing dbRecords2Process", final, cfg.DBRecordsToLoad)
 	}
 
-	elapsed := time.Since(start)
-	rps := float64(final) / math.Max(elapsed.Seconds(), 0.001)
-	log.Printf("inserted %d records in %s (%.0f rec/s)", final, elapsed.Truncate(time.Millisecond), rps)
+	logFinal(cfg.Loader, final, start)
+	logShardTotals(shardInserted)
 	return nil
 }
 
@@ -379,88 +472,24 @@ func recordsPerLog(total int, pcent int) int {
 	return step
 }
 
-type Record struct {
-	AccountUUID    string
-	Username       string
-	Email          string
-	Password       string
-	CreatedEpoch   int64
-	LastLoginEpoch int64
-
-	ProductUUID    string
-	ProductName    string
-	ProductAuthors string
-	ProductPrice   float64
-
-	PaymentMD5    string
-	PaymentAmount float64
-	PaymentEpoch  int64
-
-	Quantity    int
-	TotalAmount float64
-	BuyingEpoch int64
-}
-
-func generateRecord(r *rand.Rand, cfg Config) Record {
-	now := time.Now().Unix()
-	createdAgo := randRangeInt64(r, cfg.MinDays, cfg.MaxDays)
-	created := now - createdAgo
-	lastLogin := created + randRangeInt64(r, 0, cfg.DelayLastLogin)
-
-	price := round2(randRangeFloat(r, 1.0, 250.0))
-	qty := int(randRangeInt64(r, 1, 6))
-	total := round2(price * float64(qty))
-	paymentAmount := total
-
-	return Record{
-		AccountUUID:    faker.UUIDHyphenated(),
-		Username:       faker.Username(),
-		Email:          faker.Email(),
-		Password:       faker.Password(),
-		CreatedEpoch:   created,
-		LastLoginEpoch: lastLogin,
-
-		ProductUUID:    faker.UUIDHyphenated(),
-		ProductName:    faker.Word(),
-		ProductAuthors: strings.Join([]string{faker.Name(), faker.Name()}, ", "),
-		ProductPrice:   price,
-
-		PaymentMD5:    randomMD5(r),
-		PaymentAmount: paymentAmount,
-		PaymentEpoch:  now - randRangeInt64(r, 0, 3600*24*30),
-
-		Quantity:    qty,
-		TotalAmount: total,
-		BuyingEpoch: now - randRangeInt64(r, 0, 3600*24*30),
-	}
-}
-
+// runOnlyFaker previews the pool-and-sample model without touching the DB:
+// it generates a small account/product pool and a handful of buying_stats
+// rows sampled from it, the same way loadData's phase 3 would.
 func runOnlyFaker(_ context.Context, cfg Config) {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	numAccounts := min(cfg.NumAccounts, 10)
+	numProducts := min(cfg.NumProducts, 10)
+	accounts := generateAccounts(r, cfg, numAccounts)
+	products := generateProducts(r, cfg, numProducts)
+	acctSampler := newSampler(r, cfg, len(accounts))
+	prodSampler := newSampler(r, cfg, len(products))
+
 	limit := min(cfg.DBRecordsToLoad, 10)
 	for i := 0; i < limit; i++ {
-		rec := generateRecord(r, cfg)
-		log.Printf("faker[%d]: acct=%s email=%s product=%s price=%.2f qty=%d total=%.2f payment=%s",
-			i, rec.AccountUUID, rec.Email, rec.ProductUUID, rec.ProductPrice, rec.Quantity, rec.TotalAmount, rec.PaymentMD5)
-	}
-	log.Printf("runOnlyFaker: generated %d sample records (set runOnlyFaker=false to load DB)", limit)
-}
-
-func dsn(cfg Config, dbname string) string {
-	// Allow DB name to be empty so we can connect and run CREATE DATABASE.
-	// parseTime helps with time scanning if users extend the schema later.
-	// multiStatements=false by default.
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&charset=utf8mb4,utf8&collation=utf8mb4_unicode_ci",
-		cfg.User, cfg.Password, cfg.Host, cfg.Port, dbname)
-}
-
-func isDupKey(err error) bool {
-	// go-sql-driver/mysql returns *mysql.MySQLError, but we avoid importing the driver type
-	// to keep dependencies minimal. The error string contains "Duplicate entry".
-	if err == nil {
-		return false
+		bs := generateBuyingStat(r, accounts, products, acctSampler, prodSampler)
+		log.Printf("faker[%d]: account=%s product=%s qty=%d total=%.2f", i, bs.AccountUUID, bs.ProductUUID, bs.Quantity, bs.TotalAmount)
 	}
-	return strings.Contains(err.Error(), "Duplicate entry")
+	log.Printf("runOnlyFaker: generated %d accounts, %d products, %d sample buying_stats rows (set runOnlyFaker=false to load DB)", numAccounts, numProducts, limit)
 }
 
 func randRangeInt64(r *rand.Rand, minv, maxv int64) int64 {
@@ -481,15 +510,6 @@ func round2(f float64) float64 {
 	return math.Round(f*100) / 100
 }
 
-func randomMD5(r *rand.Rand) string {
-	var b [32]byte
-	for i := 0; i < len(b); i++ {
-		b[i] = byte(r.Intn(256))
-	}
-	sum := md5.Sum(b[:]) // #nosec G401 -- demo data only; used as a deterministic 32-char token
-	return hex.EncodeToString(sum[:])
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
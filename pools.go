@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// Distribution selects how buying_stats rows sample accounts and products.
+const (
+	distributionUniform = "uniform"
+	distributionZipf    = "zipf"
+)
+
+// Account and Product are pre-generated once per run into in-memory pools,
+// then sampled (with replacement) by generateBuyingStat so that the
+// buying_stats foreign keys carry a realistic many-to-many distribution
+// instead of every row referencing a freshly minted, never-reused account
+// and product.
+type Account struct {
+	UUID           string
+	Username       string
+	Email          string
+	Password       string
+	CreatedEpoch   int64
+	LastLoginEpoch int64
+}
+
+type Product struct {
+	UUID    string
+	Name    string
+	Authors string
+	Price   float64
+}
+
+type Payment struct {
+	MD5    string
+	Amount float64
+	Epoch  int64
+}
+
+// BuyingStat is the many-to-many row linking a sampled account/product pair.
+type BuyingStat struct {
+	AccountUUID string
+	ProductUUID string
+	Quantity    int
+	TotalAmount float64
+	BuyingEpoch int64
+}
+
+func generateAccounts(r *rand.Rand, cfg Config, n int) []Account {
+	accounts := make([]Account, n)
+	for i := range accounts {
+		created := time.Now().Unix() - randRangeInt64(r, cfg.MinDays, cfg.MaxDays)
+		accounts[i] = Account{
+			UUID:           faker.UUIDHyphenated(),
+			Username:       faker.Username(),
+			Email:          faker.Email(),
+			Password:       faker.Password(),
+			CreatedEpoch:   created,
+			LastLoginEpoch: created + randRangeInt64(r, 0, cfg.DelayLastLogin),
+		}
+	}
+	return accounts
+}
+
+func generateProducts(r *rand.Rand, cfg Config, n int) []Product {
+	products := make([]Product, n)
+	for i := range products {
+		products[i] = Product{
+			UUID:    faker.UUIDHyphenated(),
+			Name:    faker.Word(),
+			Authors: strings.Join([]string{faker.Name(), faker.Name()}, ", "),
+			Price:   round2(randRangeFloat(r, 1.0, 250.0)),
+		}
+	}
+	return products
+}
+
+func generatePayments(r *rand.Rand, cfg Config, n int) []Payment {
+	now := time.Now().Unix()
+	payments := make([]Payment, n)
+	for i := range payments {
+		payments[i] = Payment{
+			MD5:    randomMD5(r),
+			Amount: round2(randRangeFloat(r, 1.0, 250.0)),
+			Epoch:  now - randRangeInt64(r, 0, 3600*24*30),
+		}
+	}
+	return payments
+}
+
+// generateBuyingStat samples an (account, product) pair via acctSampler and
+// prodSampler, so hot/cold accounts and best-selling products emerge from
+// the sampler's distribution rather than from the data itself.
+func generateBuyingStat(r *rand.Rand, accounts []Account, products []Product, acctSampler, prodSampler sampler) BuyingStat {
+	acct := accounts[acctSampler.Sample(r)]
+	prod := products[prodSampler.Sample(r)]
+	qty := int(randRangeInt64(r, 1, 6))
+	total := round2(prod.Price * float64(qty))
+	return BuyingStat{
+		AccountUUID: acct.UUID,
+		ProductUUID: prod.UUID,
+		Quantity:    qty,
+		TotalAmount: total,
+		BuyingEpoch: time.Now().Unix() - randRangeInt64(r, 0, 3600*24*30),
+	}
+}
+
+// insertPool bulk-inserts a pool of rows (accounts, products, or payments)
+// into table in a single retried transaction, chunking each multi-row
+// INSERT to stay under maxPacketBytes.
+func insertPool(ctx context.Context, db *sql.DB, dialect Dialect, cfg Config, table string, cols []string, estBytesPerRow int, rows [][]any) error {
+	retryBaseBackoff := time.Duration(cfg.RetryBaseBackoffMS) * time.Millisecond
+	return executeTx(ctx, db, dialect, cfg.MaxRetries, retryBaseBackoff, func(tx *sql.Tx) error {
+		return insertRowsChunked(ctx, tx, dialect, table, cols, estBytesPerRow, cfg.MaxPacketBytes, rows)
+	})
+}
+
+func insertAccounts(ctx context.Context, db *sql.DB, dialect Dialect, cfg Config, accounts []Account) error {
+	rows := make([][]any, len(accounts))
+	for i, a := range accounts {
+		rows[i] = []any{a.UUID, a.Username, a.Email, a.Password, a.CreatedEpoch, a.LastLoginEpoch}
+	}
+	return insertPool(ctx, db, dialect, cfg, "accounts", accountCols, estBytesAccountRow, rows)
+}
+
+func insertProducts(ctx context.Context, db *sql.DB, dialect Dialect, cfg Config, products []Product) error {
+	rows := make([][]any, len(products))
+	for i, p := range products {
+		rows[i] = []any{p.UUID, p.Name, p.Authors, p.Price}
+	}
+	return insertPool(ctx, db, dialect, cfg, "products", productCols, estBytesProductRow, rows)
+}
+
+func insertPayments(ctx context.Context, db *sql.DB, dialect Dialect, cfg Config, payments []Payment) error {
+	rows := make([][]any, len(payments))
+	for i, p := range payments {
+		rows[i] = []any{p.MD5, p.Amount, p.Epoch}
+	}
+	return insertPool(ctx, db, dialect, cfg, "payments", paymentCols, estBytesPaymentRow, rows)
+}
+
+// sampler draws an index in [0, n) from the pool a sampler was built for.
+type sampler interface {
+	Sample(r *rand.Rand) int
+}
+
+// uniformSampler picks pool indices with equal probability.
+type uniformSampler struct{ n int }
+
+func (s uniformSampler) Sample(r *rand.Rand) int { return r.Intn(s.n) }
+
+// zipfSampler concentrates draws on the low end of the pool, producing
+// hot/cold accounts and best-selling products. It wraps math/rand.Zipf,
+// which is tied to the *rand.Rand it was built from, so a fresh instance
+// is required per worker goroutine.
+type zipfSampler struct{ z *rand.Zipf }
+
+func newZipfSampler(r *rand.Rand, exponent float64, n int) *zipfSampler {
+	return &zipfSampler{z: rand.NewZipf(r, exponent, 1, uint64(n-1))}
+}
+
+func (s *zipfSampler) Sample(r *rand.Rand) int { return int(s.z.Uint64()) }
+
+// newSampler builds the account/product sampler pair for one worker,
+// according to cfg.Distribution.
+func newSampler(r *rand.Rand, cfg Config, n int) sampler {
+	switch cfg.Distribution {
+	case distributionZipf:
+		return newZipfSampler(r, cfg.ZipfExponent, n)
+	default:
+		return uniformSampler{n: n}
+	}
+}
+
+func randomMD5(r *rand.Rand) string {
+	var b [32]byte
+	for i := 0; i < len(b); i++ {
+		b[i] = byte(r.Intn(256))
+	}
+	sum := md5.Sum(b[:]) // #nosec G401 -- demo data only; used as a deterministic 32-char token
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"math/rand"
+	"time"
+)
+
+// executeTx runs fn inside a transaction, committing on success. If fn or the
+// commit fails with an error dialect considers retryable (deadlock, lock
+// wait timeout, serialization failure, ...), it rolls back and retries up to
+// maxRetries times with exponential backoff and jitter, in the spirit of
+// crdb.ExecuteTx.
+func executeTx(ctx context.Context, db *sql.DB, dialect Dialect, maxRetries int, baseBackoff time.Duration, fn func(tx *sql.Tx) error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		var tx *sql.Tx
+		tx, err = db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(tx); err == nil {
+			err = tx.Commit()
+		} else {
+			_ = tx.Rollback()
+		}
+
+		if err == nil || attempt >= maxRetries || !dialect.IsRetryable(err) {
+			return err
+		}
+
+		backoff := expBackoffWithJitter(baseBackoff, attempt)
+		log.Printf("retrying transaction (attempt %d/%d) after %v: %v", attempt+1, maxRetries, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// expBackoffWithJitter returns base*2^attempt with up to +/-25% jitter applied,
+// so that retrying workers don't collide on the same backoff schedule.
+func expBackoffWithJitter(base time.Duration, attempt int) time.Duration {
+	d := base * time.Duration(uint64(1)<<uint(attempt))
+	jitter := time.Duration((rand.Float64()*2 - 1) * 0.25 * float64(d))
+	return d + jitter
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"hash/fnv"
+)
+
+// Shard routing strategy for buying_stats rows.
+const (
+	shardStrategyHash       = "hash"
+	shardStrategyRange      = "range"
+	shardStrategyRoundRobin = "round-robin"
+)
+
+// Shard key: which buying_stats column the routing decision is based on.
+const (
+	shardKeyAccount = "account_uuid"
+	shardKeyProduct = "product_uuid"
+)
+
+// ShardConfig is one shard's connection endpoint. A Config with no explicit
+// Shards list runs as a single implicit shard built from its own
+// Host/Port/User/Password/DBName fields, so sharding is opt-in.
+type ShardConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	DBName   string `yaml:"dbname"`
+}
+
+// resolveShards returns cfg.Shards, or a single implicit shard built from
+// cfg's top-level connection fields if none were configured.
+func resolveShards(cfg Config) []ShardConfig {
+	if len(cfg.Shards) > 0 {
+		return cfg.Shards
+	}
+	return []ShardConfig{{Host: cfg.Host, Port: cfg.Port, User: cfg.User, Password: cfg.Password, DBName: cfg.DBName}}
+}
+
+// shardKeyValue extracts the column named by cfg.ShardKey from a generated
+// buying_stats row, so it can be routed to a shard.
+func shardKeyValue(cfg Config, bs BuyingStat) string {
+	if cfg.ShardKey == shardKeyProduct {
+		return bs.ProductUUID
+	}
+	return bs.AccountUUID
+}
+
+// shardIndex picks which shard (by index into shardDBs) owns a buying_stats
+// row, according to cfg.ShardStrategy:
+//   - hash: fnv-1a hash of the shard key, so every row for the same account
+//     (or product) always lands on the same shard
+//   - round-robin: row's position in the overall job sequence mod nShards
+//   - range: the job sequence is split into nShards contiguous ranges
+//
+// idx and total are the row's position and cfg.DBRecordsToLoad, used by the
+// round-robin and range strategies.
+func shardIndex(cfg Config, key string, idx, total, nShards int) int {
+	switch cfg.ShardStrategy {
+	case shardStrategyRoundRobin:
+		return idx % nShards
+	case shardStrategyRange:
+		perShard := (total + nShards - 1) / nShards
+		if perShard < 1 {
+			perShard = 1
+		}
+		s := idx / perShard
+		if s >= nShards {
+			s = nShards - 1
+		}
+		return s
+	default: // shardStrategyHash
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(key))
+		return int(h.Sum32() % uint32(nShards))
+	}
+}